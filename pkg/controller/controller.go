@@ -18,63 +18,97 @@ package controller
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"sync"
 	"time"
 
-	"github.com/Sirupsen/logrus"
-	"github.com/skippbox/kubeless/pkg/function"
-	"github.com/skippbox/kubeless/pkg/utils"
+	"github.com/skippbox/kubeless/pkg/eventsource"
+	"github.com/skippbox/kubeless/pkg/log"
 	"github.com/skippbox/kubeless/pkg/spec"
+	"github.com/skippbox/kubeless/pkg/utils"
 
-	unversionedAPI "k8s.io/kubernetes/pkg/api/unversioned"
-	"k8s.io/kubernetes/pkg/client/unversioned"
-	"k8s.io/kubernetes/pkg/apis/extensions"
 	k8sapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/client/cache"
+	"k8s.io/kubernetes/pkg/client/record"
+	"k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/workqueue"
+	"k8s.io/kubernetes/pkg/watch"
 )
 
 const (
 	tprName = "lamb-da.k8s.io"
-)
 
-var (
-	ErrVersionOutdated = errors.New("requested version is outdated in apiserver")
-	initRetryWaitTime  = 30 * time.Second
+	// resyncPeriod is how often the informer replays the full content of its
+	// store through the event handlers, to paper over any watch events that
+	// might have been missed.
+	resyncPeriod = 30 * time.Second
+
+	// workers is the number of goroutines draining the reconcile queue.
+	workers = 2
+
+	// maxRetries is how many times a failing key is retried, with backoff,
+	// before it is given up on and surfaced as a Kubernetes Event instead.
+	maxRetries = 5
 )
 
-type rawEvent struct {
-	Type   string
-	Object json.RawMessage
-}
+var initRetryWaitTime = 30 * time.Second
 
-type Event struct {
-	Type   string
-	Object *spec.Function
+type Controller struct {
+	logger   log.Logger
+	Config   Config
+	kind     resourceKind
+	store    cache.Store
+	informer *cache.Controller
+	queue    workqueue.RateLimitingInterface
+	recorder record.EventRecorder
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	sourcesMu sync.Mutex
+	sources   map[string]runningSource
 }
 
-type Controller struct {
-	logger       *logrus.Entry
-	Config       Config
-	stopChMap    map[string]chan struct{}
-	waitFunction sync.WaitGroup
-	Functions    map[string]*spec.Function
+// runningSource is the Source currently provisioned for a function key,
+// along with the Trigger it was built from, so reconcileEventSource can tell
+// when the user has edited the trigger and the old Source needs replacing.
+type runningSource struct {
+	source  eventsource.Source
+	trigger spec.Trigger
 }
 
 type Config struct {
 	Namespace  string
 	KubeCli    *unversioned.Client
 	MasterHost string
+
+	// LeaderElect gates leader election for HA deployments of the
+	// controller. When set, only the elected leader processes Function
+	// events; the rest sit idle so a Function TPR is never reconciled twice.
+	LeaderElect bool
+
+	// LeaseDuration, RenewDeadline and RetryPeriod tune the leader election
+	// lock. They're ignored when LeaderElect is false.
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+
+	// Logger is used for all controller log output. If nil, a logrus-backed
+	// Logger is used so existing deployments see unchanged behavior.
+	Logger log.Logger
 }
 
 func New(cfg Config) *Controller {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = log.NewLogrus()
+	}
 	return &Controller{
-		logger:    logrus.WithField("pkg", "controller"),
-		Config:    cfg,
-		Functions: make(map[string]*spec.Function),
-		stopChMap: map[string]chan struct{}{},
+		logger:  logger.With(log.Fields{"pkg": "controller"}),
+		Config:  cfg,
+		stopCh:  make(chan struct{}),
+		sources: make(map[string]runningSource),
 	}
 }
 
@@ -100,194 +134,149 @@ func (c *Controller) Install() {
 	}
 }
 
+// Run starts the controller, optionally gated by leader election, and
+// blocks until Stop is called.
 func (c *Controller) Run() error {
-	var (
-		watchVersion string
-		err          error
-	)
+	c.recorder = c.newEventRecorder()
 
-	watchVersion, err = c.FindResourceVersion()
-	if err != nil {
-		return err
+	if c.Config.LeaderElect {
+		return c.runWithLeaderElection()
 	}
-
-	c.logger.Infof("starts running Kubeless controller from watch version: %s", watchVersion)
-	defer func() {
-		for _, stopC := range c.stopChMap {
-			close(stopC)
-		}
-		c.waitFunction.Wait()
-	}()
-
-	//monitor user-defined functions
-	eventCh, errCh := c.monitor(watchVersion)
-
-	go func() {
-		for event := range eventCh {
-			functionName := event.Object.ObjectMeta.Name
-			switch event.Type {
-			case "ADDED":
-				functionSpec := &event.Object.Spec
-				stopC := make(chan struct{})
-				c.stopChMap[functionName] = stopC
-				err := function.New(c.Config.KubeCli, functionName, c.Config.Namespace, functionSpec, stopC, &c.waitFunction)
-				if err != nil {
-					break
-				}
-				c.Functions[functionName] = event.Object
-				fmt.Println(c.Functions)
-				c.logger.Infof("a new function was added: %s", functionName)
-
-			case "DELETED":
-				if c.Functions[functionName] == nil {
-					c.logger.Warningf("ignore deletion: function %q not found (or dead)", functionName)
-					break
-				}
-				stopC := make(chan struct{})
-				delete(c.Functions, functionName)
-				err := function.Delete(c.Config.KubeCli, functionName, c.Config.Namespace, stopC, &c.waitFunction)
-				if err != nil {
-					break
-				}
-				fmt.Println(c.Functions)
-				c.logger.Infof("a function was deleted: %s", functionName)
-			}
-		}
-	}()
-	return <-errCh
+	return c.runController(c.stopCh)
 }
 
-func (c *Controller) initResource() error {
-	err := c.createTPR()
-	if err != nil {
-		if !utils.IsKubernetesResourceAlreadyExistError(err) {
-			return fmt.Errorf("fail to create TPR: %v", err)
-		}
-	}
-	return nil
+// Stop signals Run to shut down gracefully. It is safe to call more than
+// once, or concurrently with Run.
+func (c *Controller) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopCh)
+	})
 }
 
-func (c *Controller) FindResourceVersion() (string, error) {
-	resp, err := utils.ListResources(c.Config.MasterHost, c.Config.Namespace, c.Config.KubeCli.RESTClient.Client)
-	if err != nil {
-		return "", err
-	}
+// runController starts the informer and a pool of workers that reconcile
+// Function TPR objects against cluster state, and blocks until stop is
+// closed. Event handlers only ever enqueue a key; all actual
+// create/update/delete work happens in reconcile, so a crash or a transient
+// apiserver error just means the key gets retried instead of the function
+// being lost.
+func (c *Controller) runController(stop <-chan struct{}) error {
+	c.queue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer c.queue.ShutDown()
+
+	c.store, c.informer = cache.NewInformer(
+		c.newListWatch(),
+		&spec.Function{},
+		resyncPeriod,
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.enqueue,
+			UpdateFunc: func(_, newObj interface{}) { c.enqueue(newObj) },
+			DeleteFunc: c.enqueue,
+		},
+	)
+
+	c.logger.Infof("starts running Kubeless controller")
+	go c.informer.Run(stop)
 
-	d := json.NewDecoder(resp.Body)
-	list := &FunctionList{}
-	if err := d.Decode(list); err != nil {
-		return "", err
+	if !cache.WaitForCacheSync(stop, c.informer.HasSynced) {
+		return fmt.Errorf("timed out waiting for the Function informer cache to sync")
 	}
 
-	for _, item := range list.Items {
-		funcName := item.Name
-		c.Functions[funcName] = &item
+	for i := 0; i < workers; i++ {
+		go c.runWorker()
 	}
-	return list.ListMeta.ResourceVersion, nil
+
+	<-stop
+	return nil
 }
 
-func (c *Controller) createTPR() error {
-	tpr := &extensions.ThirdPartyResource{
-		ObjectMeta: k8sapi.ObjectMeta{
-			Name: tprName,
-		},
-		Versions: []extensions.APIVersion{
-			{Name: "v1"},
-		},
-		Description: "Kubeless: Manage serverless functions in Kubernetes",
-	}
-	_, err := c.Config.KubeCli.ThirdPartyResources().Create(tpr)
-	if err != nil {
-		return err
+func (c *Controller) newEventRecorder() record.EventRecorder {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartLogging(c.logger.Infof)
+	broadcaster.StartRecordingToSink(c.Config.KubeCli.Events(c.Config.Namespace))
+	return broadcaster.NewRecorder(k8sapi.EventSource{Component: "kubeless-controller"})
+}
+
+// resourcePath returns the apiserver path for Function objects, which
+// depends on whether initResource registered a CRD or fell back to a TPR.
+func (c *Controller) resourcePath() string {
+	if c.kind == crdResourceKind {
+		return utils.CRDResourcePath(c.Config.Namespace)
 	}
-	return nil
+	return utils.TPRResourcePath(c.Config.Namespace)
 }
 
-func (c *Controller) monitor(watchVersion string) (<-chan *Event, <-chan error) {
+func (c *Controller) newListWatch() *cache.ListWatch {
 	host := c.Config.MasterHost
-	ns := c.Config.Namespace
+	path := c.resourcePath()
 	httpClient := c.Config.KubeCli.RESTClient.Client
 
-	eventCh := make(chan *Event)
-	// On unexpected error case, controller should exit
-	errCh := make(chan error, 1)
+	return &cache.ListWatch{
+		ListFunc: func(options k8sapi.ListOptions) (runtime.Object, error) {
+			resp, err := utils.ListResources(host, path, httpClient)
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
 
-	go func() {
-		defer close(eventCh)
-		for {
-			resp, err := utils.WatchResources(host, ns, httpClient, watchVersion)
+			list := &FunctionList{}
+			if err := json.NewDecoder(resp.Body).Decode(list); err != nil {
+				return nil, err
+			}
+			return list, nil
+		},
+		WatchFunc: func(options k8sapi.ListOptions) (watch.Interface, error) {
+			resp, err := utils.WatchResources(host, path, httpClient, options.ResourceVersion)
 			if err != nil {
-				errCh <- err
-				return
+				return nil, err
 			}
 			if resp.StatusCode != 200 {
 				resp.Body.Close()
-				errCh <- errors.New("Invalid status code: " + resp.Status)
-				return
-			}
-			c.logger.Infof("start watching at %v", watchVersion)
-			decoder := json.NewDecoder(resp.Body)
-			for {
-				ev, st, err := pollEvent(decoder)
-
-				if err != nil {
-					if err == io.EOF { // apiserver will close stream periodically
-						c.logger.Debug("apiserver closed stream")
-						break
-					}
-
-					c.logger.Errorf("received invalid event from API server: %v", err)
-					errCh <- err
-					return
-				}
-
-				if st != nil {
-					if st.Code == http.StatusGone { // event history is outdated
-						errCh <- ErrVersionOutdated // go to recovery path
-						return
-					}
-					c.logger.Fatalf("unexpected status response from API server: %v", st.Message)
-				}
-
-				c.logger.Debugf("function event: %v %v", ev.Type, ev.Object.Spec)
-
-				watchVersion = ev.Object.ObjectMeta.ResourceVersion
-				eventCh <- ev
+				return nil, fmt.Errorf("invalid status code from apiserver watch: %v", resp.Status)
 			}
-
-			resp.Body.Close()
-		}
-	}()
-
-	return eventCh, errCh
+			return watch.NewStreamWatcher(&functionDecoder{
+				decoder: json.NewDecoder(resp.Body),
+				closer:  resp.Body,
+			}), nil
+		},
+	}
 }
 
-func pollEvent(decoder *json.Decoder) (*Event, *unversionedAPI.Status, error) {
-	re := &rawEvent{}
-	err := decoder.Decode(re)
-	if err != nil {
-		if err == io.EOF {
-			return nil, nil, err
+// initResource registers the Function resource with the apiserver, preferring
+// a CustomResourceDefinition and falling back to the deprecated
+// ThirdPartyResource on clusters where apiextensions.k8s.io isn't available.
+func (c *Controller) initResource() error {
+	supported, err := c.createCRD()
+	if supported {
+		if err != nil {
+			return fmt.Errorf("fail to create CustomResourceDefinition: %v", err)
 		}
-		return nil, nil, fmt.Errorf("fail to decode raw event from apiserver (%v)", err)
+		c.kind = crdResourceKind
+		return nil
 	}
 
-	if re.Type == "ERROR" {
-		status := &unversionedAPI.Status{}
-		err = json.Unmarshal(re.Object, status)
-		if err != nil {
-			return nil, nil, fmt.Errorf("fail to decode (%s) into unversioned.Status (%v)", re.Object, err)
+	c.logger.Infof("apiserver has no apiextensions.k8s.io, falling back to ThirdPartyResource")
+	if err := c.createTPR(); err != nil {
+		if !utils.IsKubernetesResourceAlreadyExistError(err) {
+			return fmt.Errorf("fail to create TPR: %v", err)
 		}
-		return nil, status, nil
 	}
+	c.kind = tprResourceKind
+	return nil
+}
 
-	ev := &Event{
-		Type:   re.Type,
-		Object: &spec.Function{},
+func (c *Controller) createTPR() error {
+	tpr := &extensions.ThirdPartyResource{
+		ObjectMeta: k8sapi.ObjectMeta{
+			Name: tprName,
+		},
+		Versions: []extensions.APIVersion{
+			{Name: "v1"},
+		},
+		Description: "Kubeless: Manage serverless functions in Kubernetes",
 	}
-	err = json.Unmarshal(re.Object, ev.Object)
+	_, err := c.Config.KubeCli.ThirdPartyResources().Create(tpr)
 	if err != nil {
-		return nil, nil, fmt.Errorf("fail to unmarshal Function object from data (%s): %v", re.Object, err)
+		return err
 	}
-	return ev, nil, nil
-}
\ No newline at end of file
+	return nil
+}
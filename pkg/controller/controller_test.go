@@ -0,0 +1,41 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStopClosesStopCh(t *testing.T) {
+	c := &Controller{stopCh: make(chan struct{})}
+
+	c.Stop()
+
+	select {
+	case <-c.stopCh:
+	case <-time.After(time.Second):
+		t.Fatal("expected Stop to close stopCh")
+	}
+}
+
+func TestStopIsSafeToCallMoreThanOnce(t *testing.T) {
+	c := &Controller{stopCh: make(chan struct{})}
+
+	c.Stop()
+	c.Stop()
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// resourceKind records which apiserver mechanism the controller registered
+// Function objects under, so the rest of the controller knows which URL
+// shape to use when listing/watching them.
+type resourceKind string
+
+const (
+	tprResourceKind resourceKind = "tpr"
+	crdResourceKind resourceKind = "crd"
+
+	crdGroup   = "functions.k8s.io"
+	crdVersion = "v1"
+	crdPlural  = "functions"
+	crdKind    = "Function"
+	crdName    = crdPlural + "." + crdGroup
+)
+
+// jsonSchemaProps is a reduced stand-in for
+// k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1.JSONSchemaProps
+// - just enough to describe the validation schema for Function objects.
+type jsonSchemaProps struct {
+	Type       string                     `json:"type,omitempty"`
+	Required   []string                   `json:"required,omitempty"`
+	Properties map[string]jsonSchemaProps `json:"properties,omitempty"`
+}
+
+// functionValidationSchema derives an OpenAPI validation schema from
+// spec.FunctionSpec, so the apiserver itself rejects malformed Function
+// objects instead of the controller discovering them at reconcile time.
+func functionValidationSchema() jsonSchemaProps {
+	return jsonSchemaProps{
+		Type:     "object",
+		Required: []string{"spec"},
+		Properties: map[string]jsonSchemaProps{
+			"spec": {
+				Type:     "object",
+				Required: []string{"handler", "function"},
+				Properties: map[string]jsonSchemaProps{
+					"handler":               {Type: "string"},
+					"function":              {Type: "string"},
+					"function-content-type": {Type: "string"},
+					"runtime":               {Type: "string"},
+					"timeout":               {Type: "string"},
+					"deps":                  {Type: "string"},
+					"replicas":              {Type: "integer"},
+				},
+			},
+		},
+	}
+}
+
+type customResourceDefinition struct {
+	APIVersion string                       `json:"apiVersion"`
+	Kind       string                       `json:"kind"`
+	Metadata   customResourceDefinitionMeta `json:"metadata"`
+	Spec       customResourceDefinitionSpec `json:"spec"`
+}
+
+type customResourceDefinitionMeta struct {
+	Name string `json:"name"`
+}
+
+type customResourceDefinitionSpec struct {
+	Group      string                        `json:"group"`
+	Version    string                        `json:"version"`
+	Scope      string                        `json:"scope"`
+	Names      customResourceDefinitionNames `json:"names"`
+	Validation *customResourceValidation     `json:"validation,omitempty"`
+}
+
+type customResourceDefinitionNames struct {
+	Plural string `json:"plural"`
+	Kind   string `json:"kind"`
+}
+
+type customResourceValidation struct {
+	OpenAPIV3Schema jsonSchemaProps `json:"openAPIV3Schema"`
+}
+
+// crdPoster is the one *http.Client method createCRD needs, kept minimal so
+// tests can point it at a httptest.Server instead of a real apiserver.
+type crdPoster interface {
+	Post(url, contentType string, body io.Reader) (*http.Response, error)
+}
+
+// createCRD registers the functions.k8s.io CustomResourceDefinition.
+// supported is false when the apiserver doesn't expose the
+// apiextensions.k8s.io/v1beta1 API at all (pre-1.7 clusters), in which case
+// the caller should fall back to createTPR.
+func (c *Controller) createCRD() (supported bool, err error) {
+	return createCRD(c.Config.KubeCli.RESTClient.Client, c.Config.MasterHost)
+}
+
+func createCRD(poster crdPoster, masterHost string) (supported bool, err error) {
+	crd := customResourceDefinition{
+		APIVersion: "apiextensions.k8s.io/v1beta1",
+		Kind:       "CustomResourceDefinition",
+		Metadata:   customResourceDefinitionMeta{Name: crdName},
+		Spec: customResourceDefinitionSpec{
+			Group:   crdGroup,
+			Version: crdVersion,
+			Scope:   "Namespaced",
+			Names: customResourceDefinitionNames{
+				Plural: crdPlural,
+				Kind:   crdKind,
+			},
+			Validation: &customResourceValidation{OpenAPIV3Schema: functionValidationSchema()},
+		},
+	}
+
+	body, err := json.Marshal(crd)
+	if err != nil {
+		return true, err
+	}
+
+	url := masterHost + "/apis/apiextensions.k8s.io/v1beta1/customresourcedefinitions"
+	resp, err := poster.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusConflict:
+		return true, nil
+	case http.StatusNotFound:
+		return false, fmt.Errorf("apiextensions.k8s.io/v1beta1 not found on this apiserver")
+	default:
+		return true, fmt.Errorf("unexpected status registering CustomResourceDefinition: %v", resp.Status)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newCRDTestServer(t *testing.T, status int) (*httptest.Server, func()) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	return srv, srv.Close
+}
+
+func TestCreateCRDSupportedOnSuccess(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusCreated, http.StatusConflict} {
+		srv, closeFn := newCRDTestServer(t, status)
+		supported, err := createCRD(srv.Client(), srv.URL)
+		closeFn()
+
+		if err != nil {
+			t.Fatalf("status %d: unexpected error: %v", status, err)
+		}
+		if !supported {
+			t.Fatalf("status %d: expected supported=true", status)
+		}
+	}
+}
+
+func TestCreateCRDUnsupportedOnNotFound(t *testing.T) {
+	srv, closeFn := newCRDTestServer(t, http.StatusNotFound)
+	defer closeFn()
+
+	supported, err := createCRD(srv.Client(), srv.URL)
+	if supported {
+		t.Fatal("expected supported=false on 404, so the caller falls back to a TPR")
+	}
+	if err == nil {
+		t.Fatal("expected an error describing the missing apiextensions.k8s.io API")
+	}
+}
+
+func TestCreateCRDErrorsOnUnexpectedStatus(t *testing.T) {
+	srv, closeFn := newCRDTestServer(t, http.StatusInternalServerError)
+	defer closeFn()
+
+	supported, err := createCRD(srv.Client(), srv.URL)
+	if !supported {
+		t.Fatal("expected supported=true on an unexpected status, since the API does exist")
+	}
+	if err == nil {
+		t.Fatal("expected an error for the unexpected status code")
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/kubernetes/pkg/client/leaderelection"
+	"k8s.io/kubernetes/pkg/client/leaderelection/resourcelock"
+)
+
+const leaderElectionLockName = "kubeless-controller"
+
+// runWithLeaderElection wraps runController so that, when several replicas
+// of the controller are running, only the elected leader reconciles
+// Functions. Followers sit in OnStartedLeading/OnStoppedLeading until the
+// leader's lease expires, at which point one of them takes over.
+func (c *Controller) runWithLeaderElection() error {
+	id, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("unable to determine hostname for leader election identity: %v", err)
+	}
+
+	lock, err := resourcelock.New(
+		resourcelock.ConfigMapsResourceLock,
+		c.Config.Namespace,
+		leaderElectionLockName,
+		c.Config.KubeCli,
+		resourcelock.ResourceLockConfig{
+			Identity:      id,
+			EventRecorder: c.recorder,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to create leader election lock: %v", err)
+	}
+
+	// runErrCh carries runController's result from the OnStartedLeading
+	// goroutine (this package's elector predates context-based cancellation,
+	// so that's the only channel it gives us) back to this one.
+	runErrCh := make(chan error, 1)
+
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: c.Config.LeaseDuration,
+		RenewDeadline: c.Config.RenewDeadline,
+		RetryPeriod:   c.Config.RetryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(stop <-chan struct{}) {
+				c.logger.Infof("%s: became leader, starting to reconcile functions", id)
+				// stop only closes when this process loses the lease; merge in
+				// c.stopCh too so a graceful shutdown also stops runController,
+				// since the elector itself has no way to be told to give up an
+				// in-progress acquire/renew early.
+				runErrCh <- c.runController(mergeStopChannels(stop, c.stopCh))
+			},
+			OnStoppedLeading: func() {
+				c.logger.Infof("%s: leader election lost, stepping down", id)
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create leader elector: %v", err)
+	}
+
+	go elector.Run()
+
+	select {
+	case err := <-runErrCh:
+		return err
+	case <-c.stopCh:
+		return nil
+	}
+}
+
+// mergeStopChannels returns a channel that closes as soon as either a or b
+// does.
+func mergeStopChannels(a, b <-chan struct{}) <-chan struct{} {
+	merged := make(chan struct{})
+	go func() {
+		defer close(merged)
+		select {
+		case <-a:
+		case <-b:
+		}
+	}()
+	return merged
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/skippbox/kubeless/pkg/eventsource"
+	"github.com/skippbox/kubeless/pkg/function"
+	"github.com/skippbox/kubeless/pkg/spec"
+
+	"k8s.io/kubernetes/pkg/client/cache"
+)
+
+// enqueue adds obj's key to the workqueue. Handlers never touch cluster
+// state directly; they only ever enqueue, so all the actual work happens in
+// reconcile and can be retried independently of the informer.
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		c.logger.Errorf("couldn't get key for object %+v: %v", obj, err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+func (c *Controller) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.reconcile(key.(string))
+	c.handleErr(err, key)
+	return true
+}
+
+// reconcile converges cluster state for the function named by key towards
+// what's in the informer's store, or tears it down if the key is no longer
+// there.
+func (c *Controller) reconcile(key string) error {
+	obj, exists, err := c.store.GetByKey(key)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		_, name, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil {
+			return err
+		}
+		c.teardownEventSource(key)
+		c.logger.Infof("a function was deleted: %s", name)
+		return function.Delete(c.Config.KubeCli, name, c.Config.Namespace)
+	}
+
+	fn := obj.(*spec.Function)
+	if err := function.Reconcile(c.Config.KubeCli, fn.Name, c.Config.Namespace, &fn.Spec); err != nil {
+		return err
+	}
+	if err := c.reconcileEventSource(key, fn); err != nil {
+		return err
+	}
+	c.logger.Infof("function was reconciled: %s", fn.Name)
+	return nil
+}
+
+// reconcileEventSource makes sure the trigger-specific plumbing (Ingress,
+// CronJob, consumer Deployment, ...) matches fn.Spec.Trigger. It's a no-op
+// once a Source for that exact Trigger is already tracked as running for
+// this key; editing the trigger tears down the old Source and starts a new
+// one. Start itself must tolerate the underlying object already existing,
+// since a controller restart starts with an empty c.sources and re-reconciles
+// every function.
+func (c *Controller) reconcileEventSource(key string, fn *spec.Function) error {
+	c.sourcesMu.Lock()
+	defer c.sourcesMu.Unlock()
+
+	if running, ok := c.sources[key]; ok {
+		if reflect.DeepEqual(running.trigger, fn.Spec.Trigger) {
+			return nil
+		}
+		if err := running.source.Stop(); err != nil {
+			c.logger.Errorf("failed to tear down previous event source for %q: %v", key, err)
+		}
+		delete(c.sources, key)
+	}
+
+	src, err := eventsource.New(c.Config.KubeCli, fn.Name, c.Config.Namespace, function.ServicePort, fn.Spec.Trigger)
+	if err != nil {
+		return err
+	}
+	if src == nil {
+		return nil
+	}
+	if err := src.Start(context.Background(), nil); err != nil {
+		return err
+	}
+	c.sources[key] = runningSource{source: src, trigger: fn.Spec.Trigger}
+	return nil
+}
+
+// teardownEventSource stops and forgets the Source running for key, if any.
+func (c *Controller) teardownEventSource(key string) {
+	c.sourcesMu.Lock()
+	defer c.sourcesMu.Unlock()
+
+	running, ok := c.sources[key]
+	if !ok {
+		return
+	}
+	delete(c.sources, key)
+	if err := running.source.Stop(); err != nil {
+		c.logger.Errorf("failed to tear down event source for %q: %v", key, err)
+	}
+}
+
+// handleErr decides whether a failed reconcile gets retried with backoff or
+// given up on. Once a key has been retried maxRetries times it's dropped
+// from the queue and a Kubernetes Event is emitted on the Function object so
+// users can see why their function never came up.
+func (c *Controller) handleErr(err error, key interface{}) {
+	if err == nil {
+		c.queue.Forget(key)
+		return
+	}
+
+	if c.queue.NumRequeues(key) < maxRetries {
+		c.logger.Errorf("error reconciling function %q, will retry: %v", key, err)
+		c.queue.AddRateLimited(key)
+		return
+	}
+
+	c.logger.Errorf("error reconciling function %q, giving up after %d retries: %v", key, maxRetries, err)
+	c.queue.Forget(key)
+
+	if obj, exists, _ := c.store.GetByKey(key.(string)); exists {
+		fn := obj.(*spec.Function)
+		c.recorder.Eventf(fn, "Warning", "ReconcileFailed", "failed to reconcile function: %v", err)
+	}
+}
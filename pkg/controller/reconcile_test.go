@@ -0,0 +1,155 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/skippbox/kubeless/pkg/eventsource"
+	"github.com/skippbox/kubeless/pkg/log"
+	"github.com/skippbox/kubeless/pkg/spec"
+
+	"k8s.io/kubernetes/pkg/client/cache"
+	"k8s.io/kubernetes/pkg/util/workqueue"
+)
+
+func newTestController() *Controller {
+	return &Controller{
+		logger:  log.NewLogrus(),
+		store:   cache.NewStore(cache.MetaNamespaceKeyFunc),
+		queue:   workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		sources: make(map[string]runningSource),
+	}
+}
+
+func TestHandleErrForgetsOnSuccess(t *testing.T) {
+	c := newTestController()
+	key := "default/foo"
+	c.queue.AddRateLimited(key)
+
+	c.handleErr(nil, key)
+
+	if c.queue.NumRequeues(key) != 0 {
+		t.Fatalf("expected NumRequeues to be reset to 0, got %d", c.queue.NumRequeues(key))
+	}
+}
+
+func TestHandleErrRetriesUnderMaxRetries(t *testing.T) {
+	c := newTestController()
+	key := "default/foo"
+
+	c.handleErr(errors.New("transient"), key)
+
+	if got := c.queue.NumRequeues(key); got != 1 {
+		t.Fatalf("expected one requeue to be recorded, got %d", got)
+	}
+}
+
+// TestHandleErrGivesUpAfterMaxRetries exercises the give-up branch with a key
+// that has no matching object in the store, so it never touches c.recorder -
+// the only way to hit this branch without standing up a real EventRecorder.
+func TestHandleErrGivesUpAfterMaxRetries(t *testing.T) {
+	c := newTestController()
+	key := "default/missing"
+
+	for i := 0; i < maxRetries; i++ {
+		c.queue.AddRateLimited(key)
+	}
+
+	c.handleErr(errors.New("still failing"), key)
+
+	if got := c.queue.NumRequeues(key); got != 0 {
+		t.Fatalf("expected queue.Forget to reset NumRequeues, got %d", got)
+	}
+}
+
+type fakeSource struct {
+	startErr  error
+	stopErr   error
+	stopCalls int
+}
+
+func (f *fakeSource) Start(ctx context.Context, invoke eventsource.InvokeFunc) error {
+	return f.startErr
+}
+
+func (f *fakeSource) Stop() error {
+	f.stopCalls++
+	return f.stopErr
+}
+
+func TestReconcileEventSourceNoopsWhenTriggerUnchanged(t *testing.T) {
+	c := newTestController()
+	key := "default/foo"
+	trigger := spec.Trigger{Type: "cron", Schedule: "* * * * *"}
+	src := &fakeSource{}
+	c.sources[key] = runningSource{source: src, trigger: trigger}
+
+	fn := &spec.Function{Spec: spec.FunctionSpec{Trigger: trigger}}
+	if err := c.reconcileEventSource(key, fn); err != nil {
+		t.Fatalf("reconcileEventSource: %v", err)
+	}
+	if src.stopCalls != 0 {
+		t.Fatalf("expected unchanged trigger not to tear down the running source, got %d Stop calls", src.stopCalls)
+	}
+	if _, ok := c.sources[key]; !ok {
+		t.Fatal("expected the running source to stay registered")
+	}
+}
+
+func TestReconcileEventSourceTearsDownOnTriggerChange(t *testing.T) {
+	c := newTestController()
+	key := "default/foo"
+	src := &fakeSource{}
+	c.sources[key] = runningSource{source: src, trigger: spec.Trigger{Type: "cron", Schedule: "* * * * *"}}
+
+	// An empty Type needs no new Source, so eventsource.New never touches
+	// c.Config.KubeCli (left nil here).
+	fn := &spec.Function{Spec: spec.FunctionSpec{Trigger: spec.Trigger{}}}
+	if err := c.reconcileEventSource(key, fn); err != nil {
+		t.Fatalf("reconcileEventSource: %v", err)
+	}
+	if src.stopCalls != 1 {
+		t.Fatalf("expected the stale source to be torn down exactly once, got %d calls", src.stopCalls)
+	}
+	if _, ok := c.sources[key]; ok {
+		t.Fatal("expected the stale source to be removed from c.sources")
+	}
+}
+
+func TestTeardownEventSourceRemovesAndStops(t *testing.T) {
+	c := newTestController()
+	key := "default/foo"
+	src := &fakeSource{}
+	c.sources[key] = runningSource{source: src, trigger: spec.Trigger{Type: "cron"}}
+
+	c.teardownEventSource(key)
+
+	if src.stopCalls != 1 {
+		t.Fatalf("expected Stop to be called once, got %d", src.stopCalls)
+	}
+	if _, ok := c.sources[key]; ok {
+		t.Fatal("expected the source to be removed from c.sources")
+	}
+}
+
+func TestTeardownEventSourceToleratesMissingKey(t *testing.T) {
+	c := newTestController()
+	c.teardownEventSource("default/never-existed")
+}
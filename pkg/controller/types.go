@@ -0,0 +1,30 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/skippbox/kubeless/pkg/spec"
+	unversionedAPI "k8s.io/kubernetes/pkg/api/unversioned"
+)
+
+// FunctionList is the TPR list wrapper returned by the apiserver for
+// "GET /apis/k8s.io/v1/namespaces/<ns>/functions".
+type FunctionList struct {
+	unversionedAPI.TypeMeta `json:",inline"`
+	ListMeta                unversionedAPI.ListMeta `json:"metadata,omitempty"`
+	Items                   []spec.Function         `json:"items"`
+}
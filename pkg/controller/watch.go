@@ -0,0 +1,68 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/skippbox/kubeless/pkg/spec"
+	unversionedAPI "k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/watch"
+)
+
+// rawEvent is the wire format the apiserver uses for TPR watch events; the
+// Function isn't registered with the runtime.Scheme so it has to be decoded
+// by hand rather than through the usual codec machinery.
+type rawEvent struct {
+	Type   string
+	Object json.RawMessage
+}
+
+// functionDecoder adapts the raw TPR watch stream into a watch.Decoder so it
+// can be driven by watch.NewStreamWatcher and, in turn, by a cache.Reflector.
+type functionDecoder struct {
+	decoder *json.Decoder
+	closer  io.Closer
+}
+
+func (d *functionDecoder) Decode() (watch.EventType, runtime.Object, error) {
+	re := &rawEvent{}
+	if err := d.decoder.Decode(re); err != nil {
+		return watch.Error, nil, err
+	}
+
+	if re.Type == "ERROR" {
+		status := &unversionedAPI.Status{}
+		if err := json.Unmarshal(re.Object, status); err != nil {
+			return watch.Error, nil, fmt.Errorf("fail to decode (%s) into unversioned.Status (%v)", re.Object, err)
+		}
+		return watch.Error, nil, fmt.Errorf("unexpected status response from apiserver: %v", status.Message)
+	}
+
+	fn := &spec.Function{}
+	if err := json.Unmarshal(re.Object, fn); err != nil {
+		return watch.Error, nil, fmt.Errorf("fail to unmarshal Function object from data (%s): %v", re.Object, err)
+	}
+	return watch.EventType(re.Type), fn, nil
+}
+
+func (d *functionDecoder) Close() {
+	d.closer.Close()
+}
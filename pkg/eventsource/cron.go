@@ -0,0 +1,64 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventsource
+
+import (
+	"context"
+	"fmt"
+
+	k8sapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/batch/v2alpha1"
+	"k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+// CronSource invokes a function on a schedule by running a CronJob that
+// curls the function's own Service - no extra runtime is needed on the
+// function side.
+type CronSource struct {
+	Client *unversioned.Client
+	Name   string
+	Ns     string
+
+	// Schedule is a standard five-field cron expression.
+	Schedule string
+	// ServicePort is the function Service's port (see pkg/function).
+	ServicePort int
+}
+
+func (s *CronSource) Start(ctx context.Context, invoke InvokeFunc) error {
+	url := fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", s.Name, s.Ns, s.ServicePort)
+
+	job := &v2alpha1.CronJob{
+		ObjectMeta: k8sapi.ObjectMeta{
+			Name:      s.Name,
+			Namespace: s.Ns,
+			Labels:    map[string]string{"function": s.Name},
+		},
+		Spec: v2alpha1.CronJobSpec{
+			Schedule: s.Schedule,
+			JobTemplate: v2alpha1.JobTemplateSpec{
+				Spec: batchJobSpec(s.Name, url),
+			},
+		},
+	}
+	_, err := s.Client.BatchV2alpha1().CronJobs(s.Ns).Create(job)
+	return ignoreAlreadyExists(err)
+}
+
+func (s *CronSource) Stop() error {
+	return s.Client.BatchV2alpha1().CronJobs(s.Ns).Delete(s.Name, nil)
+}
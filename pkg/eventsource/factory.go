@@ -0,0 +1,52 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventsource
+
+import (
+	"fmt"
+
+	"github.com/skippbox/kubeless/pkg/spec"
+	"k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+// New builds the Source for trigger, or returns a nil Source (and nil error)
+// when the function needs none. An empty trigger type means "reach it
+// through the Service kubeless already created", which needs no extra
+// plumbing; an explicit "http" trigger additionally exposes that Service
+// outside the cluster through an Ingress.
+func New(client *unversioned.Client, name, ns string, servicePort int, trigger spec.Trigger) (Source, error) {
+	switch trigger.Type {
+	case "":
+		return nil, nil
+	case "http":
+		return &HTTPSource{Client: client, Name: name, Ns: ns, Host: trigger.Host, ServicePort: servicePort}, nil
+	case "cron":
+		return &CronSource{Client: client, Name: name, Ns: ns, Schedule: trigger.Schedule, ServicePort: servicePort}, nil
+	case "kafka", "nats":
+		return &MessageQueueSource{
+			Client:      client,
+			Name:        name,
+			Ns:          ns,
+			Type:        trigger.Type,
+			Brokers:     trigger.Brokers,
+			Topic:       trigger.Topic,
+			ServicePort: servicePort,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown trigger type %q", trigger.Type)
+	}
+}
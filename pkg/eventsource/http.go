@@ -0,0 +1,75 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventsource
+
+import (
+	"context"
+
+	k8sapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/util/intstr"
+)
+
+// HTTPSource exposes a function's existing Service outside the cluster by
+// creating an Ingress that routes to it. The function is still invoked
+// through its Service, so it needs no in-process invoke callback.
+type HTTPSource struct {
+	Client *unversioned.Client
+	Name   string
+	Ns     string
+
+	// Host is the Ingress host to route; empty matches all hosts.
+	Host string
+	// ServicePort is the function Service's port (see pkg/function).
+	ServicePort int
+}
+
+func (s *HTTPSource) Start(ctx context.Context, invoke InvokeFunc) error {
+	ing := &extensions.Ingress{
+		ObjectMeta: k8sapi.ObjectMeta{
+			Name:      s.Name,
+			Namespace: s.Ns,
+			Labels:    map[string]string{"function": s.Name},
+		},
+		Spec: extensions.IngressSpec{
+			Rules: []extensions.IngressRule{
+				{
+					Host: s.Host,
+					IngressRuleValue: extensions.IngressRuleValue{
+						HTTP: &extensions.HTTPIngressRuleValue{
+							Paths: []extensions.HTTPIngressPath{
+								{
+									Backend: extensions.IngressBackend{
+										ServiceName: s.Name,
+										ServicePort: intstr.FromInt(s.ServicePort),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	_, err := s.Client.Extensions().Ingress(s.Ns).Create(ing)
+	return ignoreAlreadyExists(err)
+}
+
+func (s *HTTPSource) Stop() error {
+	return s.Client.Extensions().Ingress(s.Ns).Delete(s.Name, nil)
+}
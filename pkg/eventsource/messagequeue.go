@@ -0,0 +1,100 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventsource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	k8sapi "k8s.io/kubernetes/pkg/api"
+	unversionedAPI "k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+// bridgeImages maps a Trigger.Type to the forwarder image that consumes from
+// the broker and relays each message to the function's Service as an HTTP
+// POST. Kafka and NATS only differ in which image runs.
+var bridgeImages = map[string]string{
+	"kafka": "skippbox/kubeless-kafka-bridge",
+	"nats":  "skippbox/kubeless-nats-bridge",
+}
+
+// MessageQueueSource invokes a function for every message on a Kafka or NATS
+// topic, by running a small consumer Deployment that forwards each message
+// to the function's Service.
+type MessageQueueSource struct {
+	Client *unversioned.Client
+	Name   string
+	Ns     string
+
+	// Type is "kafka" or "nats".
+	Type    string
+	Brokers []string
+	Topic   string
+
+	// ServicePort is the function Service's port (see pkg/function).
+	ServicePort int
+}
+
+func (s *MessageQueueSource) Start(ctx context.Context, invoke InvokeFunc) error {
+	image, ok := bridgeImages[s.Type]
+	if !ok {
+		return fmt.Errorf("unsupported message queue trigger type %q", s.Type)
+	}
+
+	name := s.Name + "-trigger"
+	target := fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", s.Name, s.Ns, s.ServicePort)
+	selector := map[string]string{"function": s.Name, "trigger": s.Type}
+
+	dpm := &extensions.Deployment{
+		ObjectMeta: k8sapi.ObjectMeta{
+			Name:      name,
+			Namespace: s.Ns,
+			Labels:    selector,
+		},
+		Spec: extensions.DeploymentSpec{
+			Replicas: 1,
+			Selector: &unversionedAPI.LabelSelector{MatchLabels: selector},
+			Template: k8sapi.PodTemplateSpec{
+				ObjectMeta: k8sapi.ObjectMeta{
+					Labels: selector,
+				},
+				Spec: k8sapi.PodSpec{
+					Containers: []k8sapi.Container{
+						{
+							Name:  name,
+							Image: image,
+							Env: []k8sapi.EnvVar{
+								{Name: "BROKERS", Value: strings.Join(s.Brokers, ",")},
+								{Name: "TOPIC", Value: s.Topic},
+								{Name: "TARGET_URL", Value: target},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	_, err := s.Client.Deployments(s.Ns).Create(dpm)
+	return ignoreAlreadyExists(err)
+}
+
+func (s *MessageQueueSource) Stop() error {
+	return s.Client.Deployments(s.Ns).Delete(s.Name+"-trigger", nil)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventsource
+
+import (
+	"github.com/skippbox/kubeless/pkg/utils"
+	k8sapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/batch"
+)
+
+// ignoreAlreadyExists lets Start be called again for a trigger whose backing
+// object the apiserver already has - the case on every controller restart,
+// since the in-memory record of which Sources are running doesn't survive
+// it.
+func ignoreAlreadyExists(err error) error {
+	if err != nil && utils.IsKubernetesResourceAlreadyExistError(err) {
+		return nil
+	}
+	return err
+}
+
+const curlImage = "curlimages/curl"
+
+// batchJobSpec builds the one-shot Job run by CronSource on every tick: a
+// single curl against the function's Service.
+func batchJobSpec(name, url string) batch.JobSpec {
+	return batch.JobSpec{
+		Template: k8sapi.PodTemplateSpec{
+			ObjectMeta: k8sapi.ObjectMeta{
+				Labels: map[string]string{"function": name},
+			},
+			Spec: k8sapi.PodSpec{
+				RestartPolicy: k8sapi.RestartPolicyOnFailure,
+				Containers: []k8sapi.Container{
+					{
+						Name:    name,
+						Image:   curlImage,
+						Command: []string{"curl", "-fsS", url},
+					},
+				},
+			},
+		},
+	}
+}
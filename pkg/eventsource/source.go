@@ -0,0 +1,40 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package eventsource turns a spec.Trigger into whatever cluster-side plumbing
+// is needed to actually invoke a function: an Ingress for HTTP, a CronJob for
+// scheduled runs, or a consumer Deployment for a message queue.
+package eventsource
+
+import "context"
+
+// InvokeFunc delivers one event to the function. Sources that run outside
+// the controller process (Ingress, CronJob, consumer Deployment) don't call
+// this directly - it exists for sources that can be driven in-process, and
+// as the shared shape every Source is documented against.
+type InvokeFunc func(payload []byte, headers map[string]string) error
+
+// Source sets up (and tears down) whatever is needed to invoke a function in
+// response to events from one trigger type.
+type Source interface {
+	// Start provisions the event source. It should return once provisioning
+	// is done, not block for the source's lifetime; long-running work must
+	// watch ctx and exit when it's cancelled.
+	Start(ctx context.Context, invoke InvokeFunc) error
+
+	// Stop tears down whatever Start provisioned.
+	Stop() error
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package function
+
+import (
+	"github.com/skippbox/kubeless/pkg/spec"
+	k8sapi "k8s.io/kubernetes/pkg/api"
+	unversionedAPI "k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/util/intstr"
+)
+
+const (
+	runnerImage = "skippbox/kubeless-runner"
+
+	// ServicePort is the port a function's Service and Deployment container
+	// expose; event sources in pkg/eventsource target it directly.
+	ServicePort = 8080
+)
+
+func labels(name string) map[string]string {
+	return map[string]string{"function": name}
+}
+
+func makeDeployment(name, ns string, funcSpec *spec.FunctionSpec) *extensions.Deployment {
+	replicas := funcSpec.Replicas
+	if replicas == 0 {
+		replicas = 1
+	}
+	return &extensions.Deployment{
+		ObjectMeta: k8sapi.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			Labels:    labels(name),
+		},
+		Spec: extensions.DeploymentSpec{
+			Replicas: replicas,
+			Selector: &unversionedAPI.LabelSelector{MatchLabels: labels(name)},
+			Template: k8sapi.PodTemplateSpec{
+				ObjectMeta: k8sapi.ObjectMeta{
+					Labels: labels(name),
+				},
+				Spec: k8sapi.PodSpec{
+					Containers: []k8sapi.Container{
+						{
+							Name:  name,
+							Image: runnerImage,
+							Env: []k8sapi.EnvVar{
+								{Name: "FUNC_HANDLER", Value: funcSpec.Handler},
+								{Name: "FUNC_RUNTIME", Value: funcSpec.Runtime},
+								{Name: "FUNC_TIMEOUT", Value: funcSpec.Timeout},
+							},
+							Ports: []k8sapi.ContainerPort{
+								{ContainerPort: ServicePort},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func makeService(name, ns string) *k8sapi.Service {
+	return &k8sapi.Service{
+		ObjectMeta: k8sapi.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			Labels:    labels(name),
+		},
+		Spec: k8sapi.ServiceSpec{
+			Selector: labels(name),
+			Ports: []k8sapi.ServicePort{
+				{Port: ServicePort, TargetPort: intstr.FromInt(ServicePort)},
+			},
+		},
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package function turns a spec.Function into the Kubernetes Deployment and
+// Service that actually run the user's code.
+package function
+
+import (
+	"github.com/skippbox/kubeless/pkg/spec"
+	k8sapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+// deploymentClient is the slice of unversioned.DeploymentInterface that
+// ensureDeployment/deleteDeployment need, kept minimal so tests can fake it
+// without standing up a real client.
+type deploymentClient interface {
+	Get(name string) (*extensions.Deployment, error)
+	Create(*extensions.Deployment) (*extensions.Deployment, error)
+	Update(*extensions.Deployment) (*extensions.Deployment, error)
+	Delete(name string, options *k8sapi.DeleteOptions) error
+}
+
+// serviceClient is the slice of unversioned.ServiceInterface that
+// ensureService/deleteService need.
+type serviceClient interface {
+	Create(*k8sapi.Service) (*k8sapi.Service, error)
+	Delete(name string) error
+}
+
+// New creates the Deployment and Service that back the given function.
+func New(client *unversioned.Client, name, ns string, funcSpec *spec.FunctionSpec) error {
+	return Reconcile(client, name, ns, funcSpec)
+}
+
+// Update replaces a function's Deployment spec with one built from
+// funcSpec, so edits to the handler, runtime, timeout or replica count take
+// effect without the function being deleted first. funcSpec.Deps and
+// funcSpec.Function are not carried into the Deployment, so edits to them
+// leave it unchanged. A missing Deployment is created; unlike Reconcile,
+// Update does not touch the Service.
+func Update(client *unversioned.Client, name, ns string, funcSpec *spec.FunctionSpec) error {
+	return ensureDeployment(client.Deployments(ns), name, ns, funcSpec)
+}
+
+// Reconcile converges the cluster state for a function towards funcSpec,
+// creating or updating the Deployment and creating the Service if it is
+// missing, each independently of whether the other exists. It is safe to call repeatedly - including after a previous call
+// partially failed, e.g. created the Deployment but not the Service - which
+// is what lets the controller's workqueue retry a failed function on an
+// arbitrary backoff schedule without ever leaving it half-deployed.
+func Reconcile(client *unversioned.Client, name, ns string, funcSpec *spec.FunctionSpec) error {
+	return reconcile(client.Deployments(ns), client.Services(ns), name, ns, funcSpec)
+}
+
+func reconcile(deployments deploymentClient, services serviceClient, name, ns string, funcSpec *spec.FunctionSpec) error {
+	if err := ensureDeployment(deployments, name, ns, funcSpec); err != nil {
+		return err
+	}
+	return ensureService(services, name, ns)
+}
+
+func ensureDeployment(deployments deploymentClient, name, ns string, funcSpec *spec.FunctionSpec) error {
+	want := makeDeployment(name, ns, funcSpec)
+
+	existing, err := deployments.Get(name)
+	if errors.IsNotFound(err) {
+		_, err := deployments.Create(want)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	existing.Spec = want.Spec
+	_, err = deployments.Update(existing)
+	return err
+}
+
+func ensureService(services serviceClient, name, ns string) error {
+	_, err := services.Create(makeService(name, ns))
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// Delete removes the Deployment and Service backing a function. It
+// tolerates either already being gone, so it converges to "deleted" no
+// matter which sub-resource a previous, failed Delete call made it as far
+// as removing.
+func Delete(client *unversioned.Client, name, ns string) error {
+	if err := deleteService(client.Services(ns), name); err != nil {
+		return err
+	}
+	return deleteDeployment(client.Deployments(ns), name)
+}
+
+func deleteService(services serviceClient, name string) error {
+	if err := services.Delete(name); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func deleteDeployment(deployments deploymentClient, name string) error {
+	if err := deployments.Delete(name, nil); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package function
+
+import (
+	"testing"
+
+	"github.com/skippbox/kubeless/pkg/spec"
+	k8sapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+)
+
+func notFound(name string) error {
+	return errors.NewNotFound(unversioned.GroupResource{Resource: "deployments"}, name)
+}
+
+func alreadyExists(name string) error {
+	return errors.NewAlreadyExists(unversioned.GroupResource{Resource: "services"}, name)
+}
+
+type fakeDeployments struct {
+	byName    map[string]*extensions.Deployment
+	createErr error
+}
+
+func newFakeDeployments() *fakeDeployments {
+	return &fakeDeployments{byName: map[string]*extensions.Deployment{}}
+}
+
+func (f *fakeDeployments) Get(name string) (*extensions.Deployment, error) {
+	if d, ok := f.byName[name]; ok {
+		return d, nil
+	}
+	return nil, notFound(name)
+}
+
+func (f *fakeDeployments) Create(d *extensions.Deployment) (*extensions.Deployment, error) {
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	f.byName[d.Name] = d
+	return d, nil
+}
+
+func (f *fakeDeployments) Update(d *extensions.Deployment) (*extensions.Deployment, error) {
+	f.byName[d.Name] = d
+	return d, nil
+}
+
+func (f *fakeDeployments) Delete(name string, _ *k8sapi.DeleteOptions) error {
+	if _, ok := f.byName[name]; !ok {
+		return notFound(name)
+	}
+	delete(f.byName, name)
+	return nil
+}
+
+type fakeServices struct {
+	byName    map[string]*k8sapi.Service
+	createErr error
+}
+
+func newFakeServices() *fakeServices {
+	return &fakeServices{byName: map[string]*k8sapi.Service{}}
+}
+
+func (f *fakeServices) Create(s *k8sapi.Service) (*k8sapi.Service, error) {
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	if _, ok := f.byName[s.Name]; ok {
+		return nil, alreadyExists(s.Name)
+	}
+	f.byName[s.Name] = s
+	return s, nil
+}
+
+func (f *fakeServices) Delete(name string) error {
+	if _, ok := f.byName[name]; !ok {
+		return notFound(name)
+	}
+	delete(f.byName, name)
+	return nil
+}
+
+func TestEnsureDeploymentCreatesWhenMissing(t *testing.T) {
+	deployments := newFakeDeployments()
+	fn := &spec.FunctionSpec{Handler: "main.handler", Runtime: "python2.7"}
+
+	if err := ensureDeployment(deployments, "foo", "default", fn); err != nil {
+		t.Fatalf("ensureDeployment: %v", err)
+	}
+	if _, ok := deployments.byName["foo"]; !ok {
+		t.Fatal("expected Deployment foo to have been created")
+	}
+}
+
+func TestEnsureDeploymentUpdatesWhenPresent(t *testing.T) {
+	deployments := newFakeDeployments()
+	deployments.byName["foo"] = makeDeployment("foo", "default", &spec.FunctionSpec{Handler: "old.handler"})
+
+	fn := &spec.FunctionSpec{Handler: "new.handler"}
+	if err := ensureDeployment(deployments, "foo", "default", fn); err != nil {
+		t.Fatalf("ensureDeployment: %v", err)
+	}
+	got := deployments.byName["foo"].Spec.Template.Spec.Containers[0].Env[0].Value
+	if got != "new.handler" {
+		t.Fatalf("expected updated handler env var, got %q", got)
+	}
+}
+
+// TestReconcileCreatesServiceAfterPartialFailure is a regression test: a
+// prior Reconcile that created the Deployment but failed to create the
+// Service must not be mistaken, on retry, for a fully-deployed function.
+// Reconcile decides what to do with the Service independently of whether
+// the Deployment already exists.
+func TestReconcileCreatesServiceAfterPartialFailure(t *testing.T) {
+	deployments := newFakeDeployments()
+	deployments.byName["foo"] = makeDeployment("foo", "default", &spec.FunctionSpec{})
+	services := newFakeServices() // Service was never created.
+
+	if err := reconcile(deployments, services, "foo", "default", &spec.FunctionSpec{}); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if _, ok := services.byName["foo"]; !ok {
+		t.Fatal("expected Reconcile to create the missing Service even though the Deployment already existed")
+	}
+}
+
+func TestEnsureServiceToleratesAlreadyExists(t *testing.T) {
+	services := newFakeServices()
+	services.byName["foo"] = makeService("foo", "default")
+
+	if err := ensureService(services, "foo", "default"); err != nil {
+		t.Fatalf("ensureService should tolerate an existing Service, got: %v", err)
+	}
+}
+
+// TestDeleteDeploymentSurvivesServiceAlreadyGone is a regression test: a
+// prior Delete that removed the Service but failed to remove the Deployment
+// must still be able to remove the Deployment on retry, instead of bailing
+// out on the Service's NotFound.
+func TestDeleteDeploymentSurvivesServiceAlreadyGone(t *testing.T) {
+	deployments := newFakeDeployments()
+	deployments.byName["foo"] = makeDeployment("foo", "default", &spec.FunctionSpec{})
+	services := newFakeServices() // Service already deleted by a previous attempt.
+
+	if err := deleteService(services, "foo"); err != nil {
+		t.Fatalf("deleteService should tolerate a missing Service, got: %v", err)
+	}
+	if err := deleteDeployment(deployments, "foo"); err != nil {
+		t.Fatalf("deleteDeployment: %v", err)
+	}
+	if _, ok := deployments.byName["foo"]; ok {
+		t.Fatal("expected Deployment foo to have been deleted")
+	}
+}
+
+func TestDeleteDeploymentToleratesAlreadyGone(t *testing.T) {
+	if err := deleteDeployment(newFakeDeployments(), "missing"); err != nil {
+		t.Fatalf("deleteDeployment should tolerate a missing Deployment, got: %v", err)
+	}
+}
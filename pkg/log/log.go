@@ -0,0 +1,43 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package log defines the minimal structured logging interface kubeless
+// packages code against, so that embedding the controller in a larger
+// binary doesn't force that binary onto logrus (or any other particular
+// logging library).
+package log
+
+// Fields carries structured key/value context attached to a Logger via With.
+type Fields map[string]interface{}
+
+// Logger is the structured logger interface used throughout kubeless.
+// Implementations are expected to be safe for concurrent use.
+type Logger interface {
+	Debug(args ...interface{})
+	Debugf(format string, args ...interface{})
+	Info(args ...interface{})
+	Infof(format string, args ...interface{})
+	Warn(args ...interface{})
+	Warnf(format string, args ...interface{})
+	Error(args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatal(args ...interface{})
+	Fatalf(format string, args ...interface{})
+
+	// With returns a Logger that annotates every subsequent message with
+	// fields, in addition to whatever fields the receiver already carries.
+	With(fields Fields) Logger
+}
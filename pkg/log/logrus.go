@@ -0,0 +1,45 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import "github.com/Sirupsen/logrus"
+
+// logrusLogger is the default Logger implementation, preserving the
+// behavior kubeless has always had.
+type logrusLogger struct {
+	entry *logrus.Entry
+}
+
+// NewLogrus returns a Logger backed by logrus's standard logger.
+func NewLogrus() Logger {
+	return &logrusLogger{entry: logrus.NewEntry(logrus.StandardLogger())}
+}
+
+func (l *logrusLogger) Debug(args ...interface{})                 { l.entry.Debug(args...) }
+func (l *logrusLogger) Debugf(format string, args ...interface{}) { l.entry.Debugf(format, args...) }
+func (l *logrusLogger) Info(args ...interface{})                  { l.entry.Info(args...) }
+func (l *logrusLogger) Infof(format string, args ...interface{})  { l.entry.Infof(format, args...) }
+func (l *logrusLogger) Warn(args ...interface{})                  { l.entry.Warn(args...) }
+func (l *logrusLogger) Warnf(format string, args ...interface{})  { l.entry.Warnf(format, args...) }
+func (l *logrusLogger) Error(args ...interface{})                 { l.entry.Error(args...) }
+func (l *logrusLogger) Errorf(format string, args ...interface{}) { l.entry.Errorf(format, args...) }
+func (l *logrusLogger) Fatal(args ...interface{})                 { l.entry.Fatal(args...) }
+func (l *logrusLogger) Fatalf(format string, args ...interface{}) { l.entry.Fatalf(format, args...) }
+
+func (l *logrusLogger) With(fields Fields) Logger {
+	return &logrusLogger{entry: l.entry.WithFields(logrus.Fields(fields))}
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+// noopLogger discards everything. Useful for tests that don't want
+// controller log output cluttering their result.
+type noopLogger struct{}
+
+// NewNoop returns a Logger that discards all messages.
+func NewNoop() Logger {
+	return noopLogger{}
+}
+
+func (noopLogger) Debug(args ...interface{})                 {}
+func (noopLogger) Debugf(format string, args ...interface{}) {}
+func (noopLogger) Info(args ...interface{})                  {}
+func (noopLogger) Infof(format string, args ...interface{})  {}
+func (noopLogger) Warn(args ...interface{})                  {}
+func (noopLogger) Warnf(format string, args ...interface{})  {}
+func (noopLogger) Error(args ...interface{})                 {}
+func (noopLogger) Errorf(format string, args ...interface{}) {}
+func (noopLogger) Fatal(args ...interface{})                 {}
+func (noopLogger) Fatalf(format string, args ...interface{}) {}
+
+func (l noopLogger) With(fields Fields) Logger { return l }
@@ -0,0 +1,72 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	k8sapi "k8s.io/kubernetes/pkg/api"
+	unversionedAPI "k8s.io/kubernetes/pkg/api/unversioned"
+)
+
+// Function is the TPR object that describes a user-deployed function and
+// everything kubeless needs to know in order to run it.
+type Function struct {
+	unversionedAPI.TypeMeta `json:",inline"`
+	k8sapi.ObjectMeta       `json:"metadata,omitempty"`
+
+	Spec FunctionSpec `json:"spec"`
+}
+
+// FunctionSpec holds the user-provided function code along with the
+// runtime metadata required to turn it into a running Deployment.
+type FunctionSpec struct {
+	Handler             string `json:"handler"`
+	Function            string `json:"function"`
+	FunctionContentType string `json:"function-content-type,omitempty"`
+	Runtime             string `json:"runtime,omitempty"`
+	Timeout             string `json:"timeout,omitempty"`
+	Deps                string `json:"deps,omitempty"`
+	Replicas            int32  `json:"replicas,omitempty"`
+
+	// Trigger describes how the function gets invoked. It's optional; a zero
+	// value means "reachable through its Service like any other function",
+	// which was the only option before Trigger existed, and needs no extra
+	// plumbing. An explicit Type - including "http" - provisions trigger-
+	// specific infrastructure instead; see pkg/eventsource.
+	Trigger Trigger `json:"trigger,omitempty"`
+}
+
+// Trigger configures the event source that invokes a function.
+type Trigger struct {
+	// Type selects the event source: "" for none (the default), or "http",
+	// "cron", "kafka" or "nats".
+	Type string `json:"type,omitempty"`
+
+	// Schedule is the cron expression used when Type is "cron".
+	Schedule string `json:"schedule,omitempty"`
+
+	// Host is the Ingress host to route when Type is "http"; empty matches
+	// all hosts.
+	Host string `json:"host,omitempty"`
+
+	// Topic is the message-queue topic consumed when Type is "kafka" or
+	// "nats".
+	Topic string `json:"topic,omitempty"`
+
+	// Brokers lists the message-queue broker addresses consumed when Type is
+	// "kafka" or "nats".
+	Brokers []string `json:"brokers,omitempty"`
+}
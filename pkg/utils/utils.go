@@ -0,0 +1,74 @@
+/*
+Copyright 2016 Skippbox, Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"net/http"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+const (
+	// TPRResourceGroup is the apiserver group Function objects live under
+	// when registered as a ThirdPartyResource (pre-1.8 clusters).
+	TPRResourceGroup = "k8s.io/v1"
+
+	// CRDResourceGroup is the apiserver group Function objects live under
+	// when registered as a CustomResourceDefinition.
+	CRDResourceGroup = "functions.k8s.io/v1"
+)
+
+// TPRResourcePath returns the apiserver path for the Function
+// ThirdPartyResource in namespace ns.
+func TPRResourcePath(ns string) string {
+	return fmt.Sprintf("/apis/%s/namespaces/%s/functions", TPRResourceGroup, ns)
+}
+
+// CRDResourcePath returns the apiserver path for the Function
+// CustomResourceDefinition in namespace ns.
+func CRDResourcePath(ns string) string {
+	return fmt.Sprintf("/apis/%s/namespaces/%s/functions", CRDResourceGroup, ns)
+}
+
+// ListResources lists the Function objects found at path, which may point at
+// either a ThirdPartyResource or a CustomResourceDefinition depending on
+// which the controller registered (see TPRResourcePath/CRDResourcePath).
+func ListResources(host, path string, httpClient *http.Client) (*http.Response, error) {
+	return httpClient.Get(host + path)
+}
+
+// WatchResources opens a watch on the Function objects found at path,
+// starting at resourceVersion.
+func WatchResources(host, path string, httpClient *http.Client, resourceVersion string) (*http.Response, error) {
+	url := fmt.Sprintf("%s%s?watch=true&resourceVersion=%s", host, path, resourceVersion)
+	return httpClient.Get(url)
+}
+
+// IsKubernetesResourceAlreadyExistError returns true if err represents a 409
+// Conflict ("already exists") response from the apiserver.
+func IsKubernetesResourceAlreadyExistError(err error) bool {
+	return errors.IsAlreadyExists(err)
+}
+
+// DeployKubeless is the entry point for installing the kubeless controller
+// into the cluster. It currently creates no objects and always returns nil.
+func DeployKubeless(kubeCli *unversioned.Client) error {
+	// TODO: create the controller Deployment, Service and RBAC role bindings.
+	return nil
+}